@@ -0,0 +1,109 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+)
+
+// NewLoadCommand returns a new cobra.Command for the `kind load` verb,
+// which loads local images into a cluster's nodes without going through
+// a registry
+func NewLoadCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "load",
+		Short: "Loads images into nodes",
+	}
+	cmd.AddCommand(newLoadDockerImageCommand())
+	return cmd
+}
+
+// loadDockerImageOptions holds flags for `kind load docker-image`
+type loadDockerImageOptions struct {
+	name  string
+	nodes []string
+}
+
+// newLoadDockerImageCommand implements `kind load docker-image`
+func newLoadDockerImageCommand() *cobra.Command {
+	opts := &loadDockerImageOptions{}
+	cmd := &cobra.Command{
+		Use:   "docker-image IMAGE",
+		Short: "Loads a docker image from the host into a cluster's nodes",
+		Long: "Loads a docker image, previously built with `docker build` " +
+			"(or a `docker save` archive when IMAGE ends in .tar), into " +
+			"every node of the cluster, or only --nodes when given.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLoadDockerImage(opts, args[0])
+		},
+	}
+	cmd.Flags().StringVar(&opts.name, "name", cluster.DefaultName, "the cluster name")
+	cmd.Flags().StringSliceVar(&opts.nodes, "nodes", nil, "comma separated list of node names to load the image onto, defaults to every node")
+	return cmd
+}
+
+func runLoadDockerImage(opts *loadDockerImageOptions, image string) error {
+	ctx := cluster.NewContext(opts.name)
+
+	if len(opts.nodes) == 0 {
+		if err := ctx.LoadImage(image); err != nil {
+			return err
+		}
+		fmt.Printf("Image: %q loaded into cluster: %q\n", image, opts.name)
+		return nil
+	}
+
+	allNodes, err := ctx.ListNodes()
+	if err != nil {
+		return err
+	}
+	selected := filterNodesByName(allNodes, opts.nodes)
+	if len(selected) == 0 {
+		return fmt.Errorf("no nodes matched --nodes=%s", strings.Join(opts.nodes, ","))
+	}
+	if err := ctx.LoadImage(image, selected...); err != nil {
+		return err
+	}
+	loaded := make([]string, 0, len(selected))
+	for _, n := range selected {
+		loaded = append(loaded, n.Name())
+	}
+	fmt.Printf("Image: %q loaded into cluster: %q nodes: %s\n", image, opts.name, strings.Join(loaded, ","))
+	return nil
+}
+
+// filterNodesByName returns the subset of nodeList whose name is in names
+func filterNodesByName(nodeList []nodes.Node, names []string) []nodes.Node {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	var selected []nodes.Node
+	for _, n := range nodeList {
+		if wanted[n.Name()] {
+			selected = append(selected, n)
+		}
+	}
+	return selected
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"sigs.k8s.io/kind/pkg/cluster/config"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	logutil "sigs.k8s.io/kind/pkg/log"
+)
+
+// NodeOption is a configuration option supplied to AddNode
+type NodeOption func(*create.NodeReplica)
+
+// AddNode provisions a new node container with the given role, matching
+// the rest of the running cluster's image and labels, and runs the "join"
+// action against it so it joins the cluster
+func (c *Context) AddNode(role config.NodeRole, opts ...NodeOption) error {
+	nodeList, err := c.ListNodes()
+	if err != nil {
+		return errors.Wrap(err, "error listing nodes")
+	}
+	if len(nodeList) == 0 {
+		return errors.New("cannot add a node to a cluster with no nodes")
+	}
+
+	image, err := nodeList[0].Image()
+	if err != nil {
+		return errors.Wrap(err, "failed to determine node image")
+	}
+
+	replica := &create.NodeReplica{Role: role, Image: image}
+	for _, opt := range opts {
+		opt(replica)
+	}
+
+	cfg, derived, err := c.clusterConfigFromRunningNodes(nodeList)
+	if err != nil {
+		return err
+	}
+
+	cc := &create.Context{
+		Config:        cfg,
+		DerivedConfig: derived,
+		ClusterMeta:   c.ClusterMeta,
+	}
+	cc.Status = logutil.NewStatus(os.Stdout)
+	cc.Status.MaybeWrapLogrus(log.StandardLogger())
+	defer cc.Status.End(true)
+
+	newNode, err := cc.ProvisionNode(replica)
+	if err != nil {
+		return errors.Wrap(err, "failed to provision new node")
+	}
+
+	log.Infof("joining node %q to cluster %q", newNode.Name(), c.Name())
+	return cc.Exec(append(nodeList, newNode), []string{"join"}, cc.ExecOptions...)
+}
+
+// RemoveNode cordons and drains the node named name via the API server,
+// then deletes its container. This is the inverse of AddNode.
+func (c *Context) RemoveNode(name string) error {
+	nodeList, err := c.ListNodes()
+	if err != nil {
+		return errors.Wrap(err, "error listing nodes")
+	}
+
+	var target nodes.Node
+	for _, n := range nodeList {
+		if n.Name() == name {
+			target = n
+			break
+		}
+	}
+	if target == nil {
+		return errors.Errorf("no such node %q in cluster %q", name, c.Name())
+	}
+
+	clientset, err := c.Clientset()
+	if err != nil {
+		return errors.Wrap(err, "failed to get clientset")
+	}
+
+	if err := cordonAndDrainNode(clientset, name); err != nil {
+		return errors.Wrapf(err, "failed to cordon/drain node %q", name)
+	}
+
+	log.Infof("removing node %q from cluster %q", name, c.Name())
+	return nodes.Delete(target)
+}
+
+// cordonAndDrainNode marks name unschedulable and evicts every non
+// DaemonSet pod running on it via the eviction subresource (so
+// PodDisruptionBudgets are honored), so that RemoveNode can safely delete
+// the underlying container afterwards
+func cordonAndDrainNode(clientset kubernetes.Interface, name string) error {
+	node, err := clientset.CoreV1().Nodes().Get(name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to get node")
+	}
+	node.Spec.Unschedulable = true
+	if _, err := clientset.CoreV1().Nodes().Update(node); err != nil {
+		return errors.Wrap(err, "failed to cordon node")
+	}
+
+	pods, err := clientset.CoreV1().Pods("").List(metav1.ListOptions{
+		FieldSelector: "spec.nodeName=" + name,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to list pods on node")
+	}
+
+	for _, pod := range pods.Items {
+		if isDaemonSetPod(pod) {
+			continue
+		}
+		log.Infof("evicting pod %q/%q", pod.Namespace, pod.Name)
+		eviction := &policyv1beta1.Eviction{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pod.Name,
+				Namespace: pod.Namespace,
+			},
+		}
+		if err := clientset.PolicyV1beta1().Evictions(pod.Namespace).Evict(eviction); err != nil {
+			return errors.Wrapf(err, "failed to evict pod %q/%q", pod.Namespace, pod.Name)
+		}
+	}
+	return nil
+}
+
+// isDaemonSetPod returns true if pod is owned by a DaemonSet, which
+// should not be evicted during a drain since it will simply be
+// recreated on the same node
+func isDaemonSetPod(pod corev1.Pod) bool {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Kind == "DaemonSet" {
+			return true
+		}
+	}
+	return false
+}
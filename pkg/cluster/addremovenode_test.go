@@ -0,0 +1,64 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsDaemonSetPod(t *testing.T) {
+	cases := []struct {
+		name string
+		pod  corev1.Pod
+		want bool
+	}{
+		{
+			name: "daemonset owned pod is skipped",
+			pod: corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet"}},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "deployment owned pod is evicted",
+			pod: corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet"}},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "bare pod with no owner is evicted",
+			pod:  corev1.Pod{},
+			want: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isDaemonSetPod(tc.pod); got != tc.want {
+				t.Errorf("isDaemonSetPod() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -18,9 +18,10 @@ package cluster
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"regexp"
-	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -30,16 +31,34 @@ import (
 	"sigs.k8s.io/kind/pkg/cluster/config"
 	"sigs.k8s.io/kind/pkg/cluster/config/encoding"
 	"sigs.k8s.io/kind/pkg/cluster/internal/create"
+	"sigs.k8s.io/kind/pkg/cluster/internal/loadimage"
 	"sigs.k8s.io/kind/pkg/cluster/internal/meta"
 	"sigs.k8s.io/kind/pkg/cluster/logs"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/registry"
+	"sigs.k8s.io/kind/pkg/exec"
 	logutil "sigs.k8s.io/kind/pkg/log"
 )
 
+// registryNetworkName is the docker network that kind nodes are attached
+// to, and that an integrated registry must also join to be reachable
+const registryNetworkName = "kind"
+
+// registryHost is the host component that users actually reference the
+// integrated registry by (e.g. "localhost:5000/foo"), which is distinct
+// from registryNetworkName (the docker network used for node<->registry
+// connectivity) and is what must appear in the certs.d path and
+// registries.conf mirror key written onto each node
+const registryHost = "localhost"
+
 // Context is used to create / manipulate kubernetes-in-docker clusters
 // See: NewContext()
 type Context struct {
 	*meta.ClusterMeta
+	// kubeconfigExport records the options Create used to merge this
+	// cluster's kubeconfig into an external file, if WithKubeconfigExport
+	// was supplied; Delete uses it to remove the entries it added
+	kubeconfigExport *ExportKubeconfigOptions
 }
 
 // similar to valid docker container names, but since we will prefix
@@ -83,17 +102,129 @@ func (c *Context) Validate() error {
 // ControlPlaneMeta tracks various outputs that are relevant to the control plane created with Kind.
 // Here we can define things like ports and listen or bind addresses as needed.
 type ControlPlaneMeta struct {
-	// APIServerPort is the port that the container is forwarding to the
-	// Kubernetes API server running in the container
+	// APIServerPort is the host port that is forwarded to the
+	// Kubernetes API server, either on the external load balancer node
+	// (multi control-plane clusters) or on the sole control-plane node
 	APIServerPort int
+	// APIServerHost is the host (reachable from outside the docker
+	// network, respecting DOCKER_HOST for remote docker) that
+	// APIServerPort is published on
+	APIServerHost string
+	// LoadBalancerNodeName is the name of the external load balancer
+	// node fronting the API server, or "" if the cluster has none (in
+	// which case the sole control-plane node serves this role)
+	LoadBalancerNodeName string
+	// ControlPlaneNodeNames lists every control-plane node in the cluster
+	ControlPlaneNodeNames []string
 }
 
 // GetControlPlaneMeta attempts to retrieve / compute metadata about
 // the control plane for the context's cluster
-// NOTE: due to refactoring this is currently non-functional (!)
-// TODO(bentheelder): fix this
 func (c *Context) GetControlPlaneMeta() (*ControlPlaneMeta, error) {
-	return nil, errors.New("needs-reimplementation")
+	nodeList, err := c.ListNodes()
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing nodes")
+	}
+
+	apiServerNode, lbNodeName, controlPlaneNames, err := selectAPIServerNode(nodeList)
+	if err != nil {
+		return nil, err
+	}
+
+	port, err := apiServerPort(apiServerNode)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get API server port for node %q", apiServerNode.Name())
+	}
+
+	host, err := dockerHostAddress()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resolve docker host address")
+	}
+
+	return &ControlPlaneMeta{
+		APIServerPort:         port,
+		APIServerHost:         host,
+		LoadBalancerNodeName:  lbNodeName,
+		ControlPlaneNodeNames: controlPlaneNames,
+	}, nil
+}
+
+// selectAPIServerNode picks the node that publishes the API server port
+// out of nodeList: the external load balancer if there is one, otherwise
+// the sole control-plane node. It also returns the load balancer's name
+// (if any) and the full list of control-plane node names.
+func selectAPIServerNode(nodeList []nodes.Node) (apiServerNode nodes.Node, lbNodeName string, controlPlaneNames []string, err error) {
+	for _, n := range nodeList {
+		role, err := n.Role()
+		if err != nil {
+			return nil, "", nil, errors.Wrapf(err, "failed to get role for node %q", n.Name())
+		}
+		switch role {
+		case "external-load-balancer":
+			lbNodeName = n.Name()
+			apiServerNode = n
+		case "control-plane":
+			controlPlaneNames = append(controlPlaneNames, n.Name())
+		}
+	}
+
+	if len(controlPlaneNames) == 0 {
+		return nil, "", nil, errors.New("unable to find any control-plane node for the cluster")
+	}
+
+	// with a single control-plane node and no load balancer, the
+	// control-plane node itself publishes the API server port
+	if apiServerNode == nil {
+		if len(controlPlaneNames) != 1 {
+			return nil, "", nil, errors.New("multiple control-plane nodes found, but no external load balancer node")
+		}
+		for _, n := range nodeList {
+			if n.Name() == controlPlaneNames[0] {
+				apiServerNode = n
+				break
+			}
+		}
+	}
+
+	return apiServerNode, lbNodeName, controlPlaneNames, nil
+}
+
+// apiServerPort inspects n's docker container and returns the host port
+// published for the Kubernetes API server (container port 6443/tcp)
+func apiServerPort(n nodes.Node) (int, error) {
+	lines, err := exec.Command("docker", "inspect",
+		"--format", `{{ (index (index .NetworkSettings.Ports "6443/tcp") 0).HostPort }}`,
+		n.Name(),
+	).CombinedOutputLines()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to inspect published port for node %q", n.Name())
+	}
+	if len(lines) != 1 {
+		return 0, errors.Errorf("failed to inspect published port for node %q: unexpected output %q", n.Name(), lines)
+	}
+	port, err := strconv.Atoi(strings.TrimSpace(lines[0]))
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse published port %q", lines[0])
+	}
+	return port, nil
+}
+
+// dockerHostAddress returns the host that docker-published ports are
+// reachable on: the host component of $DOCKER_HOST for a remote docker
+// daemon, or "127.0.0.1" for the (default) local unix socket case
+func dockerHostAddress() (string, error) {
+	dockerHost := os.Getenv("DOCKER_HOST")
+	if dockerHost == "" {
+		return "127.0.0.1", nil
+	}
+	u, err := url.Parse(dockerHost)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse DOCKER_HOST %q", dockerHost)
+	}
+	if u.Hostname() == "" {
+		return "127.0.0.1", nil
+	}
+	return u.Hostname(), nil
 }
 
 // ClusterName returns the Kubernetes cluster name based on the context name
@@ -119,6 +250,36 @@ func WaitForReady(interval time.Duration) CreateOption {
 	}
 }
 
+// WithRegistry configures create to start (or reuse, if one with this name
+// is already running) a local `registry:2` container named name listening
+// on port, and to wire every node in the cluster to pull through it
+func WithRegistry(name string, port int) CreateOption {
+	return func(c *create.Context) {
+		c.Registry = &registry.Config{Name: name, Port: port}
+	}
+}
+
+// WithExistingRegistry configures create to attach the already-running
+// registry container identified by containerID to the cluster, instead of
+// creating a new one. kind will not consider itself the owner of it, and
+// will therefore leave it running on Context.Delete.
+func WithExistingRegistry(containerID string) CreateOption {
+	return func(c *create.Context) {
+		c.Registry = &registry.Config{ExistingID: containerID}
+	}
+}
+
+// WithKubeconfigExport configures create to merge (or write, per opts.Merge)
+// this cluster's kubeconfig into an external file according to opts once
+// the cluster is up, and records opts so that Context.Delete can later
+// remove the entries it added. By default Create does not touch any
+// kubeconfig file other than the one kind manages for the cluster itself.
+func WithKubeconfigExport(opts ExportKubeconfigOptions) CreateOption {
+	return func(c *create.Context) {
+		c.KubeconfigExport = &opts
+	}
+}
+
 // Create provisions and starts a kubernetes-in-docker cluster
 func (c *Context) Create(cfg *config.Config, options ...CreateOption) error {
 	// default config fields (important for usage as a library, where the config
@@ -189,34 +350,44 @@ func (c *Context) Create(cfg *config.Config, options ...CreateOption) error {
 		return err
 	}
 
-	// TODO: consider shell detection.
-	if runtime.GOOS == "windows" {
-		fmt.Printf(
-			"Cluster creation complete. To setup KUBECONFIG:\n\n"+
-
-				"For the default cmd.exe console call:\n"+
-				"kind get kubeconfig-path > kindpath\n"+
-				"set /p KUBECONFIG=<kindpath && del kindpath\n\n"+
-
-				"for PowerShell call:\n"+
-				"$env:KUBECONFIG=\"$(kind get kubeconfig-path --name=%[1]q)\"\n\n"+
+	// if a registry was requested, start or adopt it, attach it to the
+	// cluster's docker network, and point every node's containerd at it
+	if cc.Registry != nil {
+		if err := c.setupRegistry(cc.Registry, nodeList); err != nil {
+			log.Error(err)
+			if !cc.Retain {
+				c.Delete()
+			}
+			return err
+		}
+	}
 
-				"For bash on Windows:\n"+
-				"export KUBECONFIG=\"$(kind get kubeconfig-path --name=%[1]q)\"\n\n"+
+	// only touch a kubeconfig file outside kind's own if the caller opted
+	// in via WithKubeconfigExport; merging unconditionally would race
+	// other parallel Create calls over the same file and silently steal
+	// the user's current-context
+	if cc.KubeconfigExport != nil {
+		if err := c.ExportKubeconfig(*cc.KubeconfigExport); err != nil {
+			log.Error(err)
+			if !cc.Retain {
+				c.Delete()
+			}
+			return err
+		}
+		c.kubeconfigExport = cc.KubeconfigExport
 
-				"You can now use the cluster:\n"+
-				"kubectl cluster-info\n",
-			cc.Name(),
-		)
-	} else {
 		fmt.Printf(
-			"Cluster creation complete. You can now use the cluster with:\n\n"+
-
-				"export KUBECONFIG=\"$(kind get kubeconfig-path --name=%q)\"\n"+
+			"Cluster creation complete. You can now use the cluster with:\n\n" +
 				"kubectl cluster-info\n",
-			cc.Name(),
 		)
+		return nil
 	}
+
+	fmt.Printf(
+		"Cluster creation complete. You can now use the cluster with:\n\n"+
+			"export KUBECONFIG=\"%s\"\nkubectl cluster-info\n",
+		c.KubeConfigPath(),
+	)
 	return nil
 }
 
@@ -238,6 +409,25 @@ func (c *Context) Delete() error {
 		fmt.Printf("$KUBECONFIG is still set to use %s even though that file has been deleted, remember to unset it\n", c.KubeConfigPath())
 	}
 
+	// undo whatever WithKubeconfigExport merged into an external
+	// kubeconfig file on Create, if anything
+	if c.kubeconfigExport != nil {
+		if err := c.removeExportedKubeconfig(*c.kubeconfigExport); err != nil {
+			log.Warningf("error removing exported kubeconfig entries: %v", err)
+		}
+	}
+
+	// if kind created a registry for this cluster, tear it down too;
+	// registries kind merely attached to (via WithExistingRegistry /
+	// ConnectRegistry) are left running for other clusters to use
+	if owned, err := registry.OwnedByCluster(c.ClusterLabel()); err != nil {
+		log.Warningf("error looking up cluster registry: %v", err)
+	} else if owned != nil {
+		if err := registry.Delete(owned.ID); err != nil {
+			log.Warningf("error deleting cluster registry: %v", err)
+		}
+	}
+
 	return nodes.Delete(n...)
 }
 
@@ -246,6 +436,104 @@ func (c *Context) ListNodes() ([]nodes.Node, error) {
 	return nodes.List("label=" + c.ClusterLabel())
 }
 
+// ListRegistries returns every local container created from the registry:2
+// image, whether or not kind created it
+func (c *Context) ListRegistries() ([]registry.Info, error) {
+	return registry.List()
+}
+
+// ConnectRegistry attaches the already-running registry container
+// identified by id to this cluster's docker network and configures every
+// current node to pull through it. kind does not take ownership of it, so
+// Context.Delete will not remove it.
+func (c *Context) ConnectRegistry(id string) error {
+	info, err := registry.Inspect(id)
+	if err != nil {
+		return err
+	}
+	if err := registry.ConnectNetwork(info.ID, registryNetworkName); err != nil {
+		return err
+	}
+	n, err := c.ListNodes()
+	if err != nil {
+		return errors.Wrap(err, "error listing nodes")
+	}
+	return registry.Configure(n, info, registryHost)
+}
+
+// setupRegistry ensures the registry described by cfg is running, attached
+// to the cluster's network, and configured as a pull-through mirror on
+// every node in nodeList
+func (c *Context) setupRegistry(cfg *registry.Config, nodeList []nodes.Node) error {
+	if cfg.ExistingID == "" {
+		cfg.ClusterLabel = c.ClusterLabel()
+	}
+	info, err := registry.EnsureRunning(*cfg, registryNetworkName)
+	if err != nil {
+		return errors.Wrap(err, "failed to set up local registry")
+	}
+	return registry.Configure(nodeList, info, registryHost)
+}
+
+// LoadImage loads a docker image (or, if imageName ends in ".tar", a
+// previously exported image archive) onto the given nodes, importing it
+// directly into each node's containerd so it is available to the cluster
+// without an intermediate registry round trip.
+//
+// If no nodes are given, the image is loaded onto every node in the
+// cluster as returned by ListNodes().
+func (c *Context) LoadImage(imageName string, selectedNodes ...nodes.Node) error {
+	nodeList := selectedNodes
+	if len(nodeList) == 0 {
+		n, err := c.ListNodes()
+		if err != nil {
+			return errors.Wrap(err, "error listing nodes")
+		}
+		nodeList = n
+	}
+	if len(nodeList) == 0 {
+		return errors.New("no nodes found for cluster")
+	}
+
+	if strings.HasSuffix(imageName, ".tar") {
+		return loadimage.LoadArchive(imageName, nodeList)
+	}
+	return loadimage.Load(imageName, nodeList)
+}
+
+// LoadImageWithRoleSelector is like LoadImage, but restricts the target
+// nodes to those whose role matches roleSelector (e.g. "control-plane" or
+// "worker"), rather than defaulting to every node in the cluster
+func (c *Context) LoadImageWithRoleSelector(imageName string, roleSelector string) error {
+	n, err := c.ListNodes()
+	if err != nil {
+		return errors.Wrap(err, "error listing nodes")
+	}
+	selected, err := nodesWithRole(n, roleSelector)
+	if err != nil {
+		return errors.Wrap(err, "error selecting nodes by role")
+	}
+	if len(selected) == 0 {
+		return errors.Errorf("no nodes found with role %q", roleSelector)
+	}
+	return c.LoadImage(imageName, selected...)
+}
+
+// nodesWithRole returns the subset of nodeList whose role matches role
+func nodesWithRole(nodeList []nodes.Node, role string) ([]nodes.Node, error) {
+	var selected []nodes.Node
+	for _, n := range nodeList {
+		nodeRole, err := n.Role()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get role for node %q", n.Name())
+		}
+		if nodeRole == role {
+			selected = append(selected, n)
+		}
+	}
+	return selected, nil
+}
+
 // CollectLogs will populate dir with cluster logs and other debug files
 func (c *Context) CollectLogs(dir string) error {
 	nodes, err := c.ListNodes()
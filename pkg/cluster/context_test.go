@@ -0,0 +1,161 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// fakeNode is a minimal nodes.Node implementation for exercising the pure
+// selection logic in this file without shelling out to docker
+type fakeNode struct {
+	name string
+	role string
+}
+
+func (n *fakeNode) Name() string                                    { return n.name }
+func (n *fakeNode) Role() (string, error)                           { return n.role, nil }
+func (n *fakeNode) Image() (string, error)                          { return "", nil }
+func (n *fakeNode) Command(command string, args ...string) exec.Cmd { return nil }
+
+func TestSelectAPIServerNode(t *testing.T) {
+	cases := []struct {
+		name      string
+		nodeList  []nodes.Node
+		wantAPI   string
+		wantLB    string
+		wantErr   bool
+		errSubstr string
+	}{
+		{
+			name:      "no control-plane node",
+			nodeList:  []nodes.Node{&fakeNode{name: "worker1", role: "worker"}},
+			wantErr:   true,
+			errSubstr: "unable to find any control-plane node",
+		},
+		{
+			name: "multiple control-plane nodes without a load balancer",
+			nodeList: []nodes.Node{
+				&fakeNode{name: "cp1", role: "control-plane"},
+				&fakeNode{name: "cp2", role: "control-plane"},
+			},
+			wantErr:   true,
+			errSubstr: "no external load balancer node",
+		},
+		{
+			name:     "single control-plane node with no load balancer",
+			nodeList: []nodes.Node{&fakeNode{name: "cp1", role: "control-plane"}},
+			wantAPI:  "cp1",
+			wantLB:   "",
+		},
+		{
+			name: "multiple control-plane nodes with a load balancer",
+			nodeList: []nodes.Node{
+				&fakeNode{name: "cp1", role: "control-plane"},
+				&fakeNode{name: "cp2", role: "control-plane"},
+				&fakeNode{name: "lb", role: "external-load-balancer"},
+			},
+			wantAPI: "lb",
+			wantLB:  "lb",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			apiServerNode, lbNodeName, _, err := selectAPIServerNode(tc.nodeList)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got nil")
+				}
+				if !strings.Contains(err.Error(), tc.errSubstr) {
+					t.Errorf("expected error to mention %q, got %q", tc.errSubstr, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if apiServerNode.Name() != tc.wantAPI {
+				t.Errorf("apiServerNode = %q, want %q", apiServerNode.Name(), tc.wantAPI)
+			}
+			if lbNodeName != tc.wantLB {
+				t.Errorf("lbNodeName = %q, want %q", lbNodeName, tc.wantLB)
+			}
+		})
+	}
+}
+
+func TestDockerHostAddress(t *testing.T) {
+	cases := []struct {
+		name       string
+		dockerHost string
+		unset      bool
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:  "DOCKER_HOST unset",
+			unset: true,
+			want:  "127.0.0.1",
+		},
+		{
+			name:       "DOCKER_HOST empty",
+			dockerHost: "",
+			want:       "127.0.0.1",
+		},
+		{
+			name:       "DOCKER_HOST unix socket",
+			dockerHost: "unix:///var/run/docker.sock",
+			want:       "127.0.0.1",
+		},
+		{
+			name:       "DOCKER_HOST tcp",
+			dockerHost: "tcp://192.168.1.5:2376",
+			want:       "192.168.1.5",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.unset {
+				os.Unsetenv("DOCKER_HOST")
+			} else {
+				os.Setenv("DOCKER_HOST", tc.dockerHost)
+			}
+			defer os.Unsetenv("DOCKER_HOST")
+
+			got, err := dockerHostAddress()
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("dockerHostAddress() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
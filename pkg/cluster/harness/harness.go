@@ -0,0 +1,72 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package harness provides a small amount of glue for using kind as a
+// programmatic test fixture, so that other test frameworks (e2e-framework,
+// testcontainers-go, ...) don't each need to reimplement cluster naming
+// and teardown around the cluster package themselves.
+package harness
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cluster/config"
+)
+
+// rng is a package-local source seeded once at init, so that concurrently
+// running test binaries don't derive the same "random" suffix
+var rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+// rngMu guards rng, which is not safe for concurrent use on its own
+var rngMu sync.Mutex
+
+// NewEphemeralContext returns a new *cluster.Context named
+// "<prefix>-<random>", so that parallel Go tests can each stand up their
+// own cluster without colliding on name
+func NewEphemeralContext(prefix string) *cluster.Context {
+	rngMu.Lock()
+	suffix := rng.Uint32()
+	rngMu.Unlock()
+	name := fmt.Sprintf("%s-%08x", prefix, suffix)
+	return cluster.NewContext(name)
+}
+
+// WithCluster creates an ephemeral cluster from cfg, runs m.Run(), and
+// tears the cluster down again regardless of outcome. The returned int is
+// suitable for passing directly to os.Exit from a TestMain.
+func WithCluster(m *testing.M, cfg *config.Config) int {
+	ctx := NewEphemeralContext("kind-harness")
+
+	if err := ctx.Create(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, errors.Wrap(err, "failed to create harness cluster"))
+		return 1
+	}
+	defer func() {
+		if err := ctx.Delete(); err != nil {
+			fmt.Fprintln(os.Stderr, errors.Wrap(err, "failed to delete harness cluster"))
+		}
+	}()
+
+	return m.Run()
+}
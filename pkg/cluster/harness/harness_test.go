@@ -0,0 +1,34 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package harness
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewEphemeralContextUsesPrefixAndIsUnique(t *testing.T) {
+	a := NewEphemeralContext("kind-test")
+	b := NewEphemeralContext("kind-test")
+
+	if !strings.HasPrefix(a.Name(), "kind-test-") {
+		t.Errorf("expected name to start with %q, got %q", "kind-test-", a.Name())
+	}
+	if a.Name() == b.Name() {
+		t.Errorf("expected two ephemeral contexts to get distinct names, both got %q", a.Name())
+	}
+}
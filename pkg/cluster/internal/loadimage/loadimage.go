@@ -0,0 +1,84 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package loadimage implements functionality to sideload docker images
+// into the containerd instance running inside one or more kind nodes,
+// without needing to push through an intermediate registry.
+package loadimage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// Load saves image from the local docker daemon with `docker save` and
+// imports the resulting archive into every node in nodeList
+func Load(image string, nodeList []nodes.Node) error {
+	dir, err := ioutil.TempDir("", "kind-load-image")
+	if err != nil {
+		return errors.Wrap(err, "failed to create tempdir for image save")
+	}
+	defer os.RemoveAll(dir)
+
+	archive := filepath.Join(dir, "image.tar")
+	log.Infof("saving image %q to archive", image)
+	if err := exec.Command("docker", "save", "-o", archive, image).Run(); err != nil {
+		return errors.Wrapf(err, "docker save of image %q failed", image)
+	}
+
+	return LoadArchive(archive, nodeList)
+}
+
+// LoadArchive imports the docker image archive at path into every node in
+// nodeList by streaming it into the node's containerd via
+// `ctr -n=k8s.io images import`
+func LoadArchive(path string, nodeList []nodes.Node) error {
+	if len(nodeList) == 0 {
+		return errors.New("no nodes specified to load image onto")
+	}
+
+	for _, n := range nodeList {
+		if err := importArchiveToNode(path, n); err != nil {
+			return errors.Wrapf(err, "failed to load image onto node %q", n.Name())
+		}
+	}
+	return nil
+}
+
+// importArchiveToNode streams the archive at path into the node's
+// containerd content store and imports it as an image
+func importArchiveToNode(path string, n nodes.Node) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to open image archive")
+	}
+	defer f.Close()
+
+	log.Infof("loading image archive onto node %q", n.Name())
+	cmd := n.Command("ctr", "-n=k8s.io", "images", "import", "-")
+	cmd.SetStdin(f)
+	if err := cmd.Run(); err != nil {
+		return errors.Wrap(err, "ctr images import failed")
+	}
+	return nil
+}
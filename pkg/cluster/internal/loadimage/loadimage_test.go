@@ -0,0 +1,30 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadimage
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+)
+
+func TestLoadArchiveRequiresNodes(t *testing.T) {
+	err := LoadArchive("/dev/null", []nodes.Node{})
+	if err == nil {
+		t.Fatal("expected an error when no nodes are given, got nil")
+	}
+}
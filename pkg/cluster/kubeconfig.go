@@ -0,0 +1,229 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// ExportKubeconfigOptions configures ExportKubeconfig
+type ExportKubeconfigOptions struct {
+	// Path is the kubeconfig file to write to or merge into. Defaults to
+	// clientcmd.RecommendedHomeFile (usually ~/.kube/config) when empty.
+	Path string
+	// Merge, if true, upserts this cluster's entries into the existing
+	// file at Path instead of overwriting it
+	Merge bool
+	// SwitchContext, if true, sets the destination file's current-context
+	// to this cluster's context after writing
+	SwitchContext bool
+	// ContextName overrides the cluster/user/context name written to
+	// Path; it defaults to "kind-<cluster name>"
+	ContextName string
+}
+
+// kindContextName is the default name used for the cluster, user and
+// context entries written by ExportKubeconfig
+func (c *Context) kindContextName() string {
+	return "kind-" + c.Name()
+}
+
+// KubeConfig returns the in-memory kubeconfig generated for this cluster,
+// so that library callers can merge it into their own configuration
+// rather than going via a file on disk. The cluster's server address is
+// rewritten to the externally-reachable address resolved by
+// GetControlPlaneMeta, since the kubeconfig written during create points
+// at the control-plane node's in-network address.
+func (c *Context) KubeConfig() (*clientcmdapi.Config, error) {
+	cfg, err := clientcmd.LoadFromFile(c.KubeConfigPath())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load cluster kubeconfig")
+	}
+	if err := c.rewriteServerAddress(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// rewriteServerAddress points every cluster entry in cfg at the
+// host-reachable API server address for this cluster
+func (c *Context) rewriteServerAddress(cfg *clientcmdapi.Config) error {
+	meta, err := c.GetControlPlaneMeta()
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve control plane address")
+	}
+	server := fmt.Sprintf("https://%s:%d", meta.APIServerHost, meta.APIServerPort)
+	for _, cluster := range cfg.Clusters {
+		cluster.Server = server
+	}
+	return nil
+}
+
+// ExportKubeconfig writes this cluster's kubeconfig according to opts. By
+// default it overwrites opts.Path outright; with opts.Merge set it instead
+// upserts the cluster/user/context entries into whatever is already at
+// opts.Path, preserving unrelated entries, and rewrites the file
+// atomically via a temp file plus rename.
+func (c *Context) ExportKubeconfig(opts ExportKubeconfigOptions) error {
+	path := opts.Path
+	if path == "" {
+		path = clientcmd.RecommendedHomeFile
+	}
+	name := opts.ContextName
+	if name == "" {
+		name = c.kindContextName()
+	}
+
+	source, err := c.KubeConfig()
+	if err != nil {
+		return err
+	}
+	renamed := renameConfigEntries(source, name)
+
+	if !opts.Merge {
+		if !opts.SwitchContext {
+			renamed.CurrentContext = ""
+		}
+		return writeKubeconfigAtomically(path, renamed)
+	}
+
+	dest, err := loadOrNewConfig(path)
+	if err != nil {
+		return err
+	}
+	for k, v := range renamed.Clusters {
+		dest.Clusters[k] = v
+	}
+	for k, v := range renamed.AuthInfos {
+		dest.AuthInfos[k] = v
+	}
+	for k, v := range renamed.Contexts {
+		dest.Contexts[k] = v
+	}
+	if opts.SwitchContext {
+		dest.CurrentContext = name
+	}
+
+	return writeKubeconfigAtomically(path, dest)
+}
+
+// removeExportedKubeconfig removes the cluster/user/context entries that
+// ExportKubeconfig(opts) previously merged into opts.Path, undoing
+// WithKubeconfigExport. It is a no-op if opts.Path no longer exists or no
+// longer contains this cluster's entries.
+func (c *Context) removeExportedKubeconfig(opts ExportKubeconfigOptions) error {
+	path := opts.Path
+	if path == "" {
+		path = clientcmd.RecommendedHomeFile
+	}
+	name := opts.ContextName
+	if name == "" {
+		name = c.kindContextName()
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	cfg, err := loadOrNewConfig(path)
+	if err != nil {
+		return err
+	}
+	_, hasCluster := cfg.Clusters[name]
+	_, hasAuthInfo := cfg.AuthInfos[name]
+	_, hasContext := cfg.Contexts[name]
+	if !hasCluster && !hasAuthInfo && !hasContext && cfg.CurrentContext != name {
+		return nil
+	}
+
+	delete(cfg.Clusters, name)
+	delete(cfg.AuthInfos, name)
+	delete(cfg.Contexts, name)
+	if cfg.CurrentContext == name {
+		cfg.CurrentContext = ""
+	}
+
+	return writeKubeconfigAtomically(path, cfg)
+}
+
+// renameConfigEntries returns a copy of cfg with its (single) cluster,
+// user and context entries renamed to name
+func renameConfigEntries(cfg *clientcmdapi.Config, name string) *clientcmdapi.Config {
+	out := clientcmdapi.NewConfig()
+	for _, cluster := range cfg.Clusters {
+		out.Clusters[name] = cluster
+		break
+	}
+	for _, authInfo := range cfg.AuthInfos {
+		out.AuthInfos[name] = authInfo
+		break
+	}
+	for _, kctx := range cfg.Contexts {
+		kctx.Cluster = name
+		kctx.AuthInfo = name
+		out.Contexts[name] = kctx
+		break
+	}
+	out.CurrentContext = name
+	return out
+}
+
+// loadOrNewConfig loads the kubeconfig at path, or returns a fresh empty
+// config if path does not yet exist
+func loadOrNewConfig(path string) (*clientcmdapi.Config, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return clientcmdapi.NewConfig(), nil
+	}
+	cfg, err := clientcmd.LoadFromFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load kubeconfig at %q", path)
+	}
+	return cfg, nil
+}
+
+// writeKubeconfigAtomically writes cfg to path via a temp file in the
+// same directory followed by a rename, so that readers never observe a
+// partially-written kubeconfig
+func writeKubeconfigAtomically(path string, cfg *clientcmdapi.Config) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrapf(err, "failed to create directory %q", dir)
+	}
+
+	tmp, err := ioutil.TempFile(dir, ".kubeconfig-tmp-")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temp kubeconfig file")
+	}
+	tmpPath := tmp.Name()
+	tmp.Close()
+	defer os.Remove(tmpPath)
+
+	if err := clientcmd.WriteToFile(*cfg, tmpPath); err != nil {
+		return errors.Wrap(err, "failed to write kubeconfig")
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return errors.Wrapf(err, "failed to move kubeconfig into place at %q", path)
+	}
+	return nil
+}
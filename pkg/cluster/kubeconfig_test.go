@@ -0,0 +1,56 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func newSingleEntryConfig() *clientcmdapi.Config {
+	cfg := clientcmdapi.NewConfig()
+	cfg.Clusters["kubernetes"] = &clientcmdapi.Cluster{Server: "https://10.0.0.2:6443"}
+	cfg.AuthInfos["kubernetes-admin"] = &clientcmdapi.AuthInfo{Token: "tok"}
+	cfg.Contexts["kubernetes-admin@kubernetes"] = &clientcmdapi.Context{
+		Cluster:  "kubernetes",
+		AuthInfo: "kubernetes-admin",
+	}
+	cfg.CurrentContext = "kubernetes-admin@kubernetes"
+	return cfg
+}
+
+func TestRenameConfigEntriesRenamesAllThree(t *testing.T) {
+	renamed := renameConfigEntries(newSingleEntryConfig(), "kind-test")
+
+	if _, ok := renamed.Clusters["kind-test"]; !ok {
+		t.Fatalf("expected cluster entry %q, got %#v", "kind-test", renamed.Clusters)
+	}
+	if _, ok := renamed.AuthInfos["kind-test"]; !ok {
+		t.Fatalf("expected user entry %q, got %#v", "kind-test", renamed.AuthInfos)
+	}
+	kctx, ok := renamed.Contexts["kind-test"]
+	if !ok {
+		t.Fatalf("expected context entry %q, got %#v", "kind-test", renamed.Contexts)
+	}
+	if kctx.Cluster != "kind-test" || kctx.AuthInfo != "kind-test" {
+		t.Errorf("expected context to reference renamed cluster/user, got %+v", kctx)
+	}
+	if renamed.CurrentContext != "kind-test" {
+		t.Errorf("expected current-context %q, got %q", "kind-test", renamed.CurrentContext)
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/kind/pkg/cluster/config"
+	"sigs.k8s.io/kind/pkg/cluster/config/encoding"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/exec"
+	logutil "sigs.k8s.io/kind/pkg/log"
+)
+
+// nodeStartOrder lists node roles in the order they must be started in:
+// any external load balancer first, so it is up to accept connections,
+// then control-plane nodes, then workers. Stop() walks this in reverse.
+var nodeStartOrder = []string{
+	"external-load-balancer",
+	"control-plane",
+	"worker",
+}
+
+// Stop stops every node in the cluster with `docker stop`, in the reverse
+// of their start order (workers, then control-plane, then any external
+// load balancer)
+func (c *Context) Stop() error {
+	nodeList, err := c.ListNodes()
+	if err != nil {
+		return errors.Wrap(err, "error listing nodes")
+	}
+	ordered, err := orderNodesByRole(nodeList, nodeStartOrder, true)
+	if err != nil {
+		return err
+	}
+	for _, n := range ordered {
+		log.Infof("stopping node %q", n.Name())
+		if err := exec.Command("docker", "stop", n.Name()).Run(); err != nil {
+			return errors.Wrapf(err, "failed to stop node %q", n.Name())
+		}
+	}
+	return nil
+}
+
+// Start starts every previously-stopped node in the cluster with
+// `docker start`, in dependency order (any external load balancer, then
+// control-plane nodes, then workers), and re-runs the minimal subset of
+// create's actions needed for kubelet/etcd to rejoin the cluster
+func (c *Context) Start() error {
+	nodeList, err := c.ListNodes()
+	if err != nil {
+		return errors.Wrap(err, "error listing nodes")
+	}
+	ordered, err := orderNodesByRole(nodeList, nodeStartOrder, false)
+	if err != nil {
+		return err
+	}
+	for _, n := range ordered {
+		log.Infof("starting node %q", n.Name())
+		if err := exec.Command("docker", "start", n.Name()).Run(); err != nil {
+			return errors.Wrapf(err, "failed to start node %q", n.Name())
+		}
+	}
+
+	cfg, derived, err := c.clusterConfigFromRunningNodes(ordered)
+	if err != nil {
+		return err
+	}
+
+	cc := &create.Context{
+		Config:        cfg,
+		DerivedConfig: derived,
+		ClusterMeta:   c.ClusterMeta,
+	}
+	cc.Status = logutil.NewStatus(os.Stdout)
+	cc.Status.MaybeWrapLogrus(log.StandardLogger())
+	defer cc.Status.End(true)
+
+	return cc.Exec(ordered, []string{"init", "join"}, cc.ExecOptions...)
+}
+
+// clusterConfigFromRunningNodes reconstructs a config.Config (and its
+// derived form) describing the cluster as it is currently running, for
+// use by Start and AddNode, which don't have access to the in-memory
+// config.Config originally passed to Create
+func (c *Context) clusterConfigFromRunningNodes(nodeList []nodes.Node) (*config.Config, *create.DerivedConfig, error) {
+	cfg := &config.Config{}
+	for _, n := range nodeList {
+		role, err := n.Role()
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to get role for node %q", n.Name())
+		}
+		image, err := n.Image()
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to get image for node %q", n.Name())
+		}
+		cfg.Nodes = append(cfg.Nodes, config.Node{
+			Role:  config.NodeRole(role),
+			Image: image,
+		})
+	}
+
+	encoding.Scheme.Default(cfg)
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to validate cluster config reconstructed from running nodes")
+	}
+
+	derived, err := create.Derive(cfg)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to derive cluster config")
+	}
+	if err := derived.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	return cfg, derived, nil
+}
+
+// orderNodesByRole buckets nodeList by role and returns them concatenated
+// in priority order; if reverse is true, priority is walked back to front
+func orderNodesByRole(nodeList []nodes.Node, priority []string, reverse bool) ([]nodes.Node, error) {
+	byRole := make(map[string][]nodes.Node, len(priority))
+	for _, n := range nodeList {
+		role, err := n.Role()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get role for node %q", n.Name())
+		}
+		byRole[role] = append(byRole[role], n)
+	}
+
+	ordered := make([]nodes.Node, 0, len(nodeList))
+	for i := range priority {
+		role := priority[i]
+		if reverse {
+			role = priority[len(priority)-1-i]
+		}
+		ordered = append(ordered, byRole[role]...)
+	}
+	return ordered, nil
+}
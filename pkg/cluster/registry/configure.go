@@ -0,0 +1,117 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+)
+
+// hostsTOML is the containerd certs.d mirror config that makes
+// "<host>:<port>/foo" resolve to the in-network registry without TLS
+const hostsTOML = `server = "http://%[1]s:5000"
+
+[host."http://%[1]s:5000"]
+  capabilities = ["pull", "resolve", "push"]
+  skip_verify = true
+`
+
+// registriesConfPatch is appended to containerd's registries.conf so that
+// images referencing host are mirrored via the in-network registry
+const registriesConfPatch = `
+[plugins."io.containerd.grpc.v1.cri".registry.mirrors."%s"]
+  endpoint = ["http://%s:5000"]
+`
+
+// Configure writes the certs.d hosts.toml and registries.conf mirror
+// entries for info onto every node in nodeList, so that containerd
+// resolves "<host>:<port>/foo" to info without requiring TLS.
+//
+// host is the externally-visible name users reference the registry by
+// (e.g. "localhost"), which is independent of whatever docker network the
+// registry and nodes are actually connected over.
+func Configure(nodeList []nodes.Node, info *Info, host string) error {
+	hostPort := fmt.Sprintf("%s:%d", host, info.Port)
+	certsDir := fmt.Sprintf("/etc/containerd/certs.d/%s", hostPort)
+	toml := hostsTOMLFor(info.Name)
+	patch := registriesConfPatchFor(hostPort, info.Name)
+
+	marker := mirrorMarkerFor(hostPort)
+
+	for _, n := range nodeList {
+		if err := writeFile(n, certsDir+"/hosts.toml", toml); err != nil {
+			return errors.Wrapf(err, "failed to configure registry mirror on node %q", n.Name())
+		}
+		if err := appendFileIfAbsent(n, "/etc/containerd/registries.conf", marker, patch); err != nil {
+			return errors.Wrapf(err, "failed to patch registries.conf on node %q", n.Name())
+		}
+	}
+	return nil
+}
+
+// writeFile creates path (and its parent directory) inside node with the
+// given contents
+func writeFile(n nodes.Node, path, contents string) error {
+	dir := path[:strings.LastIndex(path, "/")]
+	if err := n.Command("mkdir", "-p", dir).Run(); err != nil {
+		return err
+	}
+	cmd := n.Command("cp", "/dev/stdin", path)
+	cmd.SetStdin(strings.NewReader(contents))
+	return cmd.Run()
+}
+
+// appendFileIfAbsent appends contents to the file at path inside node,
+// unless path already contains marker, so that repeated calls for the
+// same node/registry pair (e.g. ConnectRegistry called more than once)
+// don't accumulate duplicate mirror stanzas
+func appendFileIfAbsent(n nodes.Node, path, marker, contents string) error {
+	script := fmt.Sprintf("grep -qF -- %s %s 2>/dev/null || cat >> %s", shellQuote(marker), shellQuote(path), shellQuote(path))
+	cmd := n.Command("sh", "-c", script)
+	cmd.SetStdin(strings.NewReader(contents))
+	return cmd.Run()
+}
+
+// shellQuote wraps s in single quotes for safe use as a single `sh -c`
+// argument, escaping any single quotes it contains
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// hostsTOMLFor renders the certs.d hosts.toml contents that point at the
+// registry container named registryName
+func hostsTOMLFor(registryName string) string {
+	return fmt.Sprintf(hostsTOML, registryName)
+}
+
+// registriesConfPatchFor renders the registries.conf mirror stanza that
+// maps hostPort (the externally-visible host:port) to the registry
+// container named registryName
+func registriesConfPatchFor(hostPort, registryName string) string {
+	return fmt.Sprintf(registriesConfPatch, hostPort, registryName)
+}
+
+// mirrorMarkerFor returns the registries.conf table header that uniquely
+// identifies the mirror stanza for hostPort, used to detect an
+// already-patched file
+func mirrorMarkerFor(hostPort string) string {
+	return fmt.Sprintf(`[plugins."io.containerd.grpc.v1.cri".registry.mirrors."%s"]`, hostPort)
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHostsTOMLForPointsAtRegistryContainer(t *testing.T) {
+	toml := hostsTOMLFor("kind-registry")
+	if want := `server = "http://kind-registry:5000"`; !strings.Contains(toml, want) {
+		t.Errorf("hostsTOMLFor output %q does not contain %q", toml, want)
+	}
+}
+
+func TestRegistriesConfPatchForUsesExternalHostPort(t *testing.T) {
+	// the mirror key must be the externally-visible host:port (e.g.
+	// "localhost:5000"), not the docker network name, or containerd will
+	// never match it against image references
+	patch := registriesConfPatchFor("localhost:5000", "kind-registry")
+	if want := `mirrors."localhost:5000"`; !strings.Contains(patch, want) {
+		t.Errorf("registriesConfPatchFor output %q does not contain %q", patch, want)
+	}
+	if want := `endpoint = ["http://kind-registry:5000"]`; !strings.Contains(patch, want) {
+		t.Errorf("registriesConfPatchFor output %q does not contain %q", patch, want)
+	}
+}
+
+func TestMirrorMarkerForMatchesPatchHeader(t *testing.T) {
+	// the marker used to detect an already-patched registries.conf must
+	// appear verbatim in the patch itself, or appendFileIfAbsent will
+	// never find it and will keep appending duplicate stanzas
+	patch := registriesConfPatchFor("localhost:5000", "kind-registry")
+	marker := mirrorMarkerFor("localhost:5000")
+	if !strings.Contains(patch, marker) {
+		t.Errorf("mirrorMarkerFor output %q not found in registriesConfPatchFor output %q", marker, patch)
+	}
+}
+
+func TestShellQuoteEscapesSingleQuotes(t *testing.T) {
+	got := shellQuote(`it's a "test"`)
+	if want := `'it'\''s a "test"'`; got != want {
+		t.Errorf("shellQuote() = %q, want %q", got, want)
+	}
+}
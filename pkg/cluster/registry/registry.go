@@ -0,0 +1,217 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package registry implements support for an integrated local docker
+// registry that kind nodes can pull from without needing a separate push
+// to a remote registry.
+package registry
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// ownedLabel marks a registry container as having been created (and
+// therefore owned) by kind, mirroring ClusterMeta.ClusterLabel() for nodes
+const ownedLabel = "io.x-k8s.kind.registry=owned"
+
+// image is the registry image used when kind creates a new registry
+const image = "registry:2"
+
+// Config describes how a registry should be provisioned for a cluster
+type Config struct {
+	// Name is the container name to use when creating a new registry
+	Name string
+	// Port is the host port the registry's container port 5000 is
+	// published on
+	Port int
+	// ExistingID, if set, is the container ID of a pre-existing registry
+	// to reuse instead of creating a new one
+	ExistingID string
+	// ClusterLabel, if set, is recorded on a newly created registry
+	// container so that the owning cluster can find and tear it down
+	// again on delete
+	ClusterLabel string
+}
+
+// Info describes a running registry container
+type Info struct {
+	ID    string
+	Name  string
+	Port  int
+	Owned bool
+}
+
+// EnsureRunning starts a new registry container per cfg, or adopts the
+// container referenced by cfg.ExistingID, and attaches it to network so
+// that cluster nodes can reach it by name
+func EnsureRunning(cfg Config, network string) (*Info, error) {
+	if cfg.ExistingID != "" {
+		info, err := Inspect(cfg.ExistingID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to inspect existing registry %q", cfg.ExistingID)
+		}
+		if err := ConnectNetwork(info.ID, network); err != nil {
+			return nil, err
+		}
+		return info, nil
+	}
+
+	log.Infof("ensuring local registry %q is running", cfg.Name)
+	id, err := currentContainerID(cfg.Name)
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		args := []string{
+			"run", "-d",
+			"--name", cfg.Name,
+			"--restart=always",
+			"-p", fmt.Sprintf("%d:5000", cfg.Port),
+			"--label", ownedLabel,
+		}
+		if cfg.ClusterLabel != "" {
+			args = append(args, "--label", cfg.ClusterLabel)
+		}
+		args = append(args, image)
+		if err := exec.Command("docker", args...).Run(); err != nil {
+			return nil, errors.Wrap(err, "failed to start local registry container")
+		}
+		id, err = currentContainerID(cfg.Name)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to locate registry container after creation")
+		}
+		if id == "" {
+			return nil, errors.Errorf("failed to locate registry container %q after creation", cfg.Name)
+		}
+	}
+
+	if err := ConnectNetwork(id, network); err != nil {
+		return nil, err
+	}
+
+	return &Info{ID: id, Name: cfg.Name, Port: cfg.Port, Owned: true}, nil
+}
+
+// ConnectNetwork attaches the registry container id to the docker network,
+// tolerating the (benign) error returned when it is already attached
+func ConnectNetwork(id, network string) error {
+	err := exec.Command("docker", "network", "connect", network, id).Run()
+	if err != nil && !strings.Contains(err.Error(), "already exists") {
+		return errors.Wrapf(err, "failed to connect registry %q to network %q", id, network)
+	}
+	return nil
+}
+
+// Inspect returns Info for an already-running registry container,
+// including the host port it publishes 5000/tcp on
+func Inspect(id string) (*Info, error) {
+	format := "{{.Name}}||{{index .Config.Labels \"" + strings.Split(ownedLabel, "=")[0] + "\"}}" +
+		"||{{ (index (index .NetworkSettings.Ports \"5000/tcp\") 0).HostPort }}"
+	lines, err := exec.Command("docker", "inspect", "-f", format, id).CombinedOutputLines()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to inspect container %q", id)
+	}
+	if len(lines) == 0 {
+		return nil, errors.Errorf("failed to inspect container %q: no output", id)
+	}
+	parts := strings.SplitN(lines[0], "||", 3)
+	name := strings.TrimPrefix(parts[0], "/")
+	owned := len(parts) > 1 && parts[1] == "owned"
+	port := 0
+	if len(parts) > 2 {
+		port, err = strconv.Atoi(strings.TrimSpace(parts[2]))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse published port for container %q", id)
+		}
+	}
+	return &Info{ID: id, Name: name, Port: port, Owned: owned}, nil
+}
+
+// List returns every container created from the registry:2 image, whether
+// or not kind owns it; registries running any other image are not listed
+func List() ([]Info, error) {
+	lines, err := exec.Command("docker", "ps", "-a",
+		"--filter", "ancestor="+image,
+		"--format", "{{.ID}}",
+	).CombinedOutputLines()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list registry containers")
+	}
+	infos := []Info{}
+	for _, id := range lines {
+		if id == "" {
+			continue
+		}
+		info, err := Inspect(id)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, *info)
+	}
+	return infos, nil
+}
+
+// OwnedByCluster returns the registry container kind created (if any) for
+// the cluster identified by clusterLabel, e.g. as returned by
+// ClusterMeta.ClusterLabel()
+func OwnedByCluster(clusterLabel string) (*Info, error) {
+	lines, err := exec.Command("docker", "ps", "-a",
+		"--filter", "label="+ownedLabel,
+		"--filter", "label="+clusterLabel,
+		"--format", "{{.ID}}",
+	).CombinedOutputLines()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to look up owned registry container")
+	}
+	if len(lines) == 0 || lines[0] == "" {
+		return nil, nil
+	}
+	return Inspect(lines[0])
+}
+
+// Delete removes the registry container identified by id. Callers are
+// responsible for only calling Delete on registries they own.
+func Delete(id string) error {
+	if id == "" {
+		return nil
+	}
+	log.Infof("deleting local registry %q", id)
+	return exec.Command("docker", "rm", "-f", "-v", id).Run()
+}
+
+// currentContainerID returns the container ID for the container named
+// name, or "" if no such container exists
+func currentContainerID(name string) (string, error) {
+	lines, err := exec.Command("docker", "ps", "-a",
+		"--filter", "name=^"+name+"$",
+		"--format", "{{.ID}}",
+	).CombinedOutputLines()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to look up container %q", name)
+	}
+	if len(lines) == 0 || lines[0] == "" {
+		return "", nil
+	}
+	return lines[0], nil
+}
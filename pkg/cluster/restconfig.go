@@ -0,0 +1,97 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// RESTConfig returns a *rest.Config for talking to this cluster's API
+// server, built from KubeConfig() so that, unlike the raw kubeconfig file
+// written by Create(), it points at the host-reachable API server
+// address resolved by GetControlPlaneMeta
+func (c *Context) RESTConfig() (*rest.Config, error) {
+	kubeConfig, err := c.KubeConfig()
+	if err != nil {
+		return nil, err
+	}
+	restConfig, err := clientcmd.NewDefaultClientConfig(*kubeConfig, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build REST config from kubeconfig")
+	}
+	return restConfig, nil
+}
+
+// Clientset returns a ready-to-use kubernetes.Interface for this cluster,
+// so that library callers (and tests) don't need to import client-go
+// plumbing themselves
+func (c *Context) Clientset() (kubernetes.Interface, error) {
+	restConfig, err := c.RESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+	return clientset, nil
+}
+
+// WaitForNodesReady blocks until every Kubernetes Node in the cluster
+// reports Ready=True, or ctx is done
+func (c *Context) WaitForNodesReady(ctx context.Context) error {
+	clientset, err := c.Clientset()
+	if err != nil {
+		return err
+	}
+	return wait.PollImmediateUntil(time.Second, func() (bool, error) {
+		nodeList, err := clientset.CoreV1().Nodes().List(metav1.ListOptions{})
+		if err != nil {
+			// the API server may not be up yet, keep polling
+			return false, nil
+		}
+		if len(nodeList.Items) == 0 {
+			return false, nil
+		}
+		for _, n := range nodeList.Items {
+			if !nodeIsReady(n) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}, ctx.Done())
+}
+
+// nodeIsReady returns true if node's Ready condition is True
+func nodeIsReady(node corev1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNodeIsReady(t *testing.T) {
+	cases := []struct {
+		name       string
+		conditions []corev1.NodeCondition
+		want       bool
+	}{
+		{
+			name:       "no conditions at all",
+			conditions: nil,
+			want:       false,
+		},
+		{
+			name: "ready condition true",
+			conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			},
+			want: true,
+		},
+		{
+			name: "ready condition false",
+			conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionFalse},
+			},
+			want: false,
+		},
+		{
+			name: "only unrelated conditions present",
+			conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeDiskPressure, Status: corev1.ConditionFalse},
+			},
+			want: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			node := corev1.Node{Status: corev1.NodeStatus{Conditions: tc.conditions}}
+			if got := nodeIsReady(node); got != tc.want {
+				t.Errorf("nodeIsReady() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}